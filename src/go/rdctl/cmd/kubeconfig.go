@@ -0,0 +1,299 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/machine"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+const guestKubeconfigPath = "/etc/rancher/k3s/k3s.yaml"
+const rancherDesktopContextName = "rancher-desktop"
+
+var (
+	kubeconfigOutputFile    string
+	kubeconfigMerge         bool
+	kubeconfigSwitchContext bool
+	kubeconfigServer        string
+)
+
+// kubeconfigCmd represents the kubeconfig command
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Fetch the cluster's kubeconfig from the Rancher Desktop VM",
+	Long: `Fetch the cluster's kubeconfig from the Rancher Desktop VM and either print
+it (the default), write it to a file with --output, or merge it into the
+user's existing kubeconfig (honoring $KUBECONFIG) with --merge, under the
+"rancher-desktop" context.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doKubeconfigCommand(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kubeconfigCmd)
+	kubeconfigCmd.Flags().StringVarP(&kubeconfigOutputFile, "output", "o", "", "Write the kubeconfig to this file instead of printing it.")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigMerge, "merge", false, "Merge the kubeconfig into $KUBECONFIG (or ~/.kube/config) instead of printing it.")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigSwitchContext, "switch-context", false, "Make rancher-desktop the current context. Implies --merge.")
+	kubeconfigCmd.Flags().StringVar(&kubeconfigServer, "server", "", "Rewrite the API server URL, e.g. for use from another machine.")
+}
+
+func doKubeconfigCommand(cmd *cobra.Command) error {
+	if kubeconfigOutputFile != "" && (kubeconfigMerge || kubeconfigSwitchContext) {
+		return fmt.Errorf("--output cannot be combined with --merge or --switch-context")
+	}
+
+	raw, err := fetchGuestKubeconfig()
+	if err != nil {
+		return err
+	}
+
+	if kubeconfigServer != "" {
+		raw, err = rewriteServerURL(raw, kubeconfigServer)
+		if err != nil {
+			return fmt.Errorf("failed to rewrite API server URL: %w", err)
+		}
+	}
+
+	raw, err = renameContext(raw, rancherDesktopContextName)
+	if err != nil {
+		return fmt.Errorf("failed to rename context: %w", err)
+	}
+
+	if kubeconfigOutputFile != "" {
+		return os.WriteFile(kubeconfigOutputFile, raw, 0o600)
+	}
+
+	if kubeconfigMerge || kubeconfigSwitchContext {
+		return mergeIntoUserKubeconfig(raw)
+	}
+
+	_, err = cmd.OutOrStdout().Write(raw)
+	return err
+}
+
+// fetchGuestKubeconfig reads the k3s kubeconfig out of the guest VM over
+// `limactl shell` (or `wsl` on Windows). It does not consult --transport or
+// RDCTL_TRANSPORT, so it never uses the ssh backend.
+func fetchGuestKubeconfig() ([]byte, error) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	if runtime.GOOS == "windows" {
+		name, _ := resolveVMName("", "")
+		backend, err := machine.NewExecBackend(machine.TransportWSL, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := backend.Exec(name, machine.RunOptions{
+			Args:   []string{"cat", guestKubeconfigPath},
+			Stdout: &stdout,
+			Stderr: &stderr,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to read kubeconfig from WSL: %w: %s", err, stderr.String())
+		}
+		return stdout.Bytes(), nil
+	}
+
+	resolvedPaths, err := paths.Get()
+	if err != nil {
+		return nil, err
+	}
+	os.Setenv("PATH", fmt.Sprintf("%s:%s", filepath.Dir(resolvedPaths.LimaCtl()), os.Getenv("PATH")))
+	os.Setenv("LIMA_HOME", resolvedPaths.LimaHome())
+
+	provider, err := machine.NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	name, _ := resolveVMName("0", "")
+	if err := provider.Run(name, machine.RunOptions{
+		Args:   []string{"cat", guestKubeconfigPath},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig from %s: %w: %s", name, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// renameContext renames the (single) context, cluster, and user in raw to
+// newName, so the merged kubeconfig doesn't collide with an existing
+// "default" entry.
+func renameContext(raw []byte, newName string) ([]byte, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+
+	renameNamedEntries(config, "clusters", newName)
+	renameNamedEntries(config, "users", newName)
+
+	if contexts, ok := config["contexts"].([]interface{}); ok {
+		for _, c := range contexts {
+			context, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			context["name"] = newName
+			if spec, ok := context["context"].(map[string]interface{}); ok {
+				spec["cluster"] = newName
+				spec["user"] = newName
+			}
+		}
+	}
+	config["current-context"] = newName
+
+	return yaml.Marshal(config)
+}
+
+func renameNamedEntries(config map[string]interface{}, key string, newName string) {
+	entries, ok := config[key].([]interface{})
+	if !ok {
+		return
+	}
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry["name"] = newName
+	}
+}
+
+// rewriteServerURL replaces the `server:` field of the (single) cluster
+// entry in raw with server.
+func rewriteServerURL(raw []byte, server string) ([]byte, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+	clusters, ok := config["clusters"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig has no clusters")
+	}
+	for _, c := range clusters {
+		cluster, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if spec, ok := cluster["cluster"].(map[string]interface{}); ok {
+			spec["server"] = server
+		}
+	}
+	return yaml.Marshal(config)
+}
+
+// mergeIntoUserKubeconfig merges raw into the kubeconfig pointed to by
+// $KUBECONFIG (or ~/.kube/config if unset), overwriting any existing
+// rancher-desktop entries, and optionally switches the current context.
+func mergeIntoUserKubeconfig(raw []byte) error {
+	targetPath, err := userKubeconfigPath()
+	if err != nil {
+		return err
+	}
+
+	var target map[string]interface{}
+	if existing, err := os.ReadFile(targetPath); err == nil {
+		if err := yaml.Unmarshal(existing, &target); err != nil {
+			return fmt.Errorf("failed to parse existing kubeconfig at %s: %w", targetPath, err)
+		}
+	}
+	if target == nil {
+		target = map[string]interface{}{"apiVersion": "v1", "kind": "Config"}
+	}
+
+	var incoming map[string]interface{}
+	if err := yaml.Unmarshal(raw, &incoming); err != nil {
+		return err
+	}
+
+	mergeNamedEntries(target, incoming, "clusters")
+	mergeNamedEntries(target, incoming, "users")
+	mergeNamedEntries(target, incoming, "contexts")
+
+	if kubeconfigSwitchContext {
+		target["current-context"] = rancherDesktopContextName
+	} else if _, ok := target["current-context"]; !ok {
+		target["current-context"] = rancherDesktopContextName
+	}
+
+	merged, err := yaml.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(targetPath, merged, 0o600)
+}
+
+// mergeNamedEntries merges the named list at key from incoming into
+// target, replacing any entry in target that shares a name with one in
+// incoming.
+func mergeNamedEntries(target map[string]interface{}, incoming map[string]interface{}, key string) {
+	existing, _ := target[key].([]interface{})
+	additions, _ := incoming[key].([]interface{})
+
+	incomingNames := make(map[string]bool, len(additions))
+	for _, a := range additions {
+		if entry, ok := a.(map[string]interface{}); ok {
+			if name, ok := entry["name"].(string); ok {
+				incomingNames[name] = true
+			}
+		}
+	}
+
+	filtered := existing[:0]
+	for _, e := range existing {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			filtered = append(filtered, e)
+			continue
+		}
+		if name, ok := entry["name"].(string); ok && incomingNames[name] {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	target[key] = append(filtered, additions...)
+}
+
+// userKubeconfigPath returns the path to the user's kubeconfig, honoring
+// $KUBECONFIG (the first entry, if it lists several) and otherwise
+// defaulting to ~/.kube/config, matching kubectl's own lookup rules (which
+// don't consider $XDG_CONFIG_HOME).
+func userKubeconfigPath() (string, error) {
+	if kubeconfigEnv := os.Getenv("KUBECONFIG"); kubeconfigEnv != "" {
+		return filepath.SplitList(kubeconfigEnv)[0], nil
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		return "", fmt.Errorf("neither KUBECONFIG nor HOME is set")
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
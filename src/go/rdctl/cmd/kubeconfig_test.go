@@ -0,0 +1,243 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const sampleKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority-data: AAAA
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+users:
+- name: default
+  user:
+    token: BBBB
+current-context: default
+`
+
+func TestRenameContextRenamesClusterUserAndContext(t *testing.T) {
+	raw, err := renameContext([]byte(sampleKubeconfig), rancherDesktopContextName)
+	if err != nil {
+		t.Fatalf("renameContext returned an error: %v", err)
+	}
+
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		t.Fatalf("renameContext produced invalid YAML: %v", err)
+	}
+
+	clusters := config["clusters"].([]interface{})
+	if name := clusters[0].(map[string]interface{})["name"]; name != rancherDesktopContextName {
+		t.Fatalf("cluster name = %v, want %q", name, rancherDesktopContextName)
+	}
+	users := config["users"].([]interface{})
+	if name := users[0].(map[string]interface{})["name"]; name != rancherDesktopContextName {
+		t.Fatalf("user name = %v, want %q", name, rancherDesktopContextName)
+	}
+	contexts := config["contexts"].([]interface{})
+	context := contexts[0].(map[string]interface{})
+	if name := context["name"]; name != rancherDesktopContextName {
+		t.Fatalf("context name = %v, want %q", name, rancherDesktopContextName)
+	}
+	spec := context["context"].(map[string]interface{})
+	if spec["cluster"] != rancherDesktopContextName || spec["user"] != rancherDesktopContextName {
+		t.Fatalf("context spec = %+v, want cluster/user both %q", spec, rancherDesktopContextName)
+	}
+	if config["current-context"] != rancherDesktopContextName {
+		t.Fatalf("current-context = %v, want %q", config["current-context"], rancherDesktopContextName)
+	}
+}
+
+func TestRewriteServerURLReplacesServer(t *testing.T) {
+	const newServer = "https://example.com:6443"
+	raw, err := rewriteServerURL([]byte(sampleKubeconfig), newServer)
+	if err != nil {
+		t.Fatalf("rewriteServerURL returned an error: %v", err)
+	}
+
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		t.Fatalf("rewriteServerURL produced invalid YAML: %v", err)
+	}
+	clusters := config["clusters"].([]interface{})
+	spec := clusters[0].(map[string]interface{})["cluster"].(map[string]interface{})
+	if spec["server"] != newServer {
+		t.Fatalf("server = %v, want %q", spec["server"], newServer)
+	}
+}
+
+func TestRewriteServerURLErrorsWithoutClusters(t *testing.T) {
+	if _, err := rewriteServerURL([]byte("apiVersion: v1\nkind: Config\n"), "https://example.com"); err == nil {
+		t.Fatal("expected an error for a kubeconfig with no clusters, got nil")
+	}
+}
+
+// withKubeconfigEnv points $KUBECONFIG at a fresh path in a temp directory
+// and resets the kubeconfigSwitchContext flag, so tests can call
+// mergeIntoUserKubeconfig without touching the real user kubeconfig.
+func withKubeconfigEnv(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path)
+	kubeconfigSwitchContext = false
+	t.Cleanup(func() { kubeconfigSwitchContext = false })
+	return path
+}
+
+func TestMergeIntoUserKubeconfigCreatesMissingFile(t *testing.T) {
+	path := withKubeconfigEnv(t)
+
+	raw, err := renameContext([]byte(sampleKubeconfig), rancherDesktopContextName)
+	if err != nil {
+		t.Fatalf("renameContext returned an error: %v", err)
+	}
+	if err := mergeIntoUserKubeconfig(raw); err != nil {
+		t.Fatalf("mergeIntoUserKubeconfig returned an error: %v", err)
+	}
+
+	merged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read merged kubeconfig: %v", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("merged kubeconfig is invalid YAML: %v", err)
+	}
+	clusters := config["clusters"].([]interface{})
+	if len(clusters) != 1 || clusters[0].(map[string]interface{})["name"] != rancherDesktopContextName {
+		t.Fatalf("clusters = %+v, want a single %q entry", clusters, rancherDesktopContextName)
+	}
+	if config["current-context"] != rancherDesktopContextName {
+		t.Fatalf("current-context = %v, want %q (should default when the target had none)", config["current-context"], rancherDesktopContextName)
+	}
+}
+
+func TestMergeIntoUserKubeconfigReplacesExistingEntry(t *testing.T) {
+	path := withKubeconfigEnv(t)
+
+	existing := `
+apiVersion: v1
+kind: Config
+clusters:
+- name: rancher-desktop
+  cluster:
+    server: https://stale:6443
+- name: other-cluster
+  cluster:
+    server: https://other:6443
+contexts:
+- name: other-cluster
+  context:
+    cluster: other-cluster
+    user: other-cluster
+current-context: other-cluster
+`
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("failed to seed existing kubeconfig: %v", err)
+	}
+
+	raw, err := renameContext([]byte(sampleKubeconfig), rancherDesktopContextName)
+	if err != nil {
+		t.Fatalf("renameContext returned an error: %v", err)
+	}
+	if err := mergeIntoUserKubeconfig(raw); err != nil {
+		t.Fatalf("mergeIntoUserKubeconfig returned an error: %v", err)
+	}
+
+	merged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read merged kubeconfig: %v", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("merged kubeconfig is invalid YAML: %v", err)
+	}
+
+	clusters := config["clusters"].([]interface{})
+	if len(clusters) != 2 {
+		t.Fatalf("clusters = %+v, want the stale rancher-desktop entry replaced, other-cluster kept", clusters)
+	}
+	var rancherDesktop, other map[string]interface{}
+	for _, c := range clusters {
+		cluster := c.(map[string]interface{})
+		switch cluster["name"] {
+		case rancherDesktopContextName:
+			rancherDesktop = cluster["cluster"].(map[string]interface{})
+		case "other-cluster":
+			other = cluster["cluster"].(map[string]interface{})
+		}
+	}
+	if rancherDesktop == nil || rancherDesktop["server"] == "https://stale:6443" {
+		t.Fatalf("rancher-desktop cluster = %+v, want the stale entry overwritten with the new one", rancherDesktop)
+	}
+	if other == nil {
+		t.Fatalf("other-cluster entry was dropped by the merge")
+	}
+
+	// current-context was already set, so merging shouldn't touch it
+	// without --switch-context.
+	if config["current-context"] != "other-cluster" {
+		t.Fatalf("current-context = %v, want it left as \"other-cluster\"", config["current-context"])
+	}
+}
+
+func TestMergeIntoUserKubeconfigSwitchContext(t *testing.T) {
+	path := withKubeconfigEnv(t)
+	kubeconfigSwitchContext = true
+
+	if err := os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+current-context: other-cluster
+`), 0o600); err != nil {
+		t.Fatalf("failed to seed existing kubeconfig: %v", err)
+	}
+
+	raw, err := renameContext([]byte(sampleKubeconfig), rancherDesktopContextName)
+	if err != nil {
+		t.Fatalf("renameContext returned an error: %v", err)
+	}
+	if err := mergeIntoUserKubeconfig(raw); err != nil {
+		t.Fatalf("mergeIntoUserKubeconfig returned an error: %v", err)
+	}
+
+	merged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read merged kubeconfig: %v", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("merged kubeconfig is invalid YAML: %v", err)
+	}
+	if config["current-context"] != rancherDesktopContextName {
+		t.Fatalf("current-context = %v, want %q with --switch-context set", config["current-context"], rancherDesktopContextName)
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verboseLogging bool
+	debugLogging   bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&verboseLogging, "verbose", "v", false, "Show informational output (can also be set via RDCTL_LOG_LEVEL=info).")
+	rootCmd.PersistentFlags().BoolVar(&debugLogging, "debug", false, "Show debug output (can also be set via RDCTL_LOG_LEVEL=debug).")
+	cobra.OnInitialize(func() {
+		logging.Init(verboseLogging, debugLogging)
+	})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/machine"
+)
+
+// machineCmd is the parent of the `rdctl machine` subcommand tree, which
+// manages the VM backing Rancher Desktop (Lima on macOS/Linux, WSL on
+// Windows) the way `podman machine` manages podman's VMs.
+var machineCmd = &cobra.Command{
+	Use:   "machine",
+	Short: "Manage Rancher Desktop's virtual machine(s)",
+}
+
+func init() {
+	rootCmd.AddCommand(machineCmd)
+	machineCmd.AddCommand(machineListCmd)
+	machineCmd.AddCommand(machineInitCmd)
+	machineCmd.AddCommand(machineStartCmd)
+	machineCmd.AddCommand(machineStopCmd)
+	machineCmd.AddCommand(machineRmCmd)
+	machineCmd.AddCommand(machineInspectCmd)
+	machineCmd.AddCommand(machineSshCmd)
+	machineCmd.AddCommand(machineSetCmd)
+}
+
+var machineListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List VM instances",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		instances, err := provider.List()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("NAME\tSTATUS\n")
+		for _, inst := range instances {
+			fmt.Printf("%s\t%s\n", inst.Name, inst.Status)
+		}
+		return nil
+	},
+}
+
+var machineInitCmd = &cobra.Command{
+	Use:   "init <name>",
+	Short: "Create a new VM instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		return provider.Init(args[0])
+	},
+}
+
+var machineStartCmd = &cobra.Command{
+	Use:   "start <name>",
+	Short: "Start a stopped VM instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		return provider.Start(args[0])
+	},
+}
+
+var machineStopCmd = &cobra.Command{
+	Use:   "stop <name>",
+	Short: "Stop a running VM instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		return provider.Stop(args[0])
+	},
+}
+
+var machineRmForce bool
+
+var machineRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a VM instance",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		return provider.Remove(args[0], machineRmForce)
+	},
+}
+
+var machineInspectCmd = &cobra.Command{
+	Use:   "inspect <name>",
+	Short: "Show details about a VM instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		inst, err := provider.Inspect(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Name:   %s\n", inst.Name)
+		fmt.Printf("Status: %s\n", inst.Status)
+		fmt.Printf("Arch:   %s\n", inst.Arch)
+		fmt.Printf("CPUs:   %d\n", inst.CPUs)
+		fmt.Printf("Memory: %d\n", inst.Memory)
+		fmt.Printf("Disk:   %d\n", inst.Disk)
+		return nil
+	},
+}
+
+var machineSshTransport string
+
+var machineSshCmd = &cobra.Command{
+	Use:   "ssh <name> [-- <command>]",
+	Short: "Run an interactive shell or a command in a VM instance",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		transport, err := resolveTransport(machineSshTransport)
+		if err != nil {
+			return err
+		}
+		backend, err := machine.NewExecBackend(transport, args[0])
+		if err != nil {
+			return err
+		}
+		return backend.Exec(args[0], machine.RunOptions{
+			Args:   args[1:],
+			Stdin:  os.Stdin,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		})
+	},
+}
+
+var (
+	machineSetCPUs   int
+	machineSetMemory int
+)
+
+var machineSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Update the configuration of a VM instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("cpus") && !cmd.Flags().Changed("memory") {
+			return fmt.Errorf("must specify at least one of --cpus or --memory")
+		}
+		provider, err := machine.NewProvider()
+		if err != nil {
+			return err
+		}
+		return provider.Set(args[0], machineSetCPUs, machineSetMemory)
+	},
+}
+
+func init() {
+	machineRmCmd.Flags().BoolVarP(&machineRmForce, "force", "f", false, "Remove the instance even if it is running.")
+	machineSshCmd.Flags().StringVar(&machineSshTransport, "transport", string(machine.TransportAuto), "How to reach the VM: auto, limactl, ssh, or wsl (can also be set via RDCTL_TRANSPORT).")
+	machineSetCmd.Flags().IntVar(&machineSetCPUs, "cpus", 0, "Number of CPUs to assign to the instance.")
+	machineSetCmd.Flags().IntVar(&machineSetMemory, "memory", 0, "Memory (in GiB) to assign to the instance.")
+}
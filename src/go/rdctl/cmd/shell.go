@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/machine"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 )
 
 // shellCmd represents the shell command
@@ -36,105 +38,159 @@ var shellCmd = &cobra.Command{
 > rdctl shell
 -- Runs an interactive shell
 > rdctl shell echo "An embedded ; ls thing"
--- Echoes back "An embedded ; ls thing".`,
+-- Echoes back "An embedded ; ls thing".
+> rdctl shell myvm -- echo hello
+-- Runs "echo hello" in the Lima instance or WSL distribution named "myvm".
+
+Use --name/-n (or the RDCTL_VM environment variable) to target a Lima
+instance or WSL distribution other than the default Rancher Desktop VM.
+A positional selector before "--" works the same way, and takes priority
+over RDCTL_VM (but not --name/-n).
+
+Use --transport to choose how the command reaches the VM: "auto" (the
+default) prefers a direct SSH connection and falls back to limactl/wsl,
+"ssh" requires it, and "limactl"/"wsl" force the VM-native client.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return doShellCommand(cmd, args)
 	},
 }
 
 var initialWindowsShellDirectory string
+var vmName string
+var vmTransport string
 
 func init() {
 	rootCmd.AddCommand(shellCmd)
 	if runtime.GOOS == "windows" {
 		startCmd.Flags().StringVar(&initialWindowsShellDirectory, "cd", "", "Directory to run command in.")
 	}
+	shellCmd.Flags().StringVarP(&vmName, "name", "n", "", "Name of the Lima instance or WSL distribution to enter (default: the Rancher Desktop VM; can also be set via RDCTL_VM).")
+	shellCmd.Flags().StringVar(&vmTransport, "transport", string(machine.TransportAuto), "How to reach the VM: auto, limactl, ssh, or wsl (can also be set via RDCTL_TRANSPORT).")
 }
 
-func doShellCommand(cmd *cobra.Command, args []string) error {
-	fmt.Fprintf(os.Stderr, "QQQ: args: %v\n", args)
-	var commandName string
-	if runtime.GOOS == "windows" {
-		commandName = "wsl"
-		if initialWindowsShellDirectory != "" {
-			args = append([]string{"--cd", initialWindowsShellDirectory}, args...)
+// resolveTransport returns the transport to use, in precedence order: flagValue
+// (if set to something other than its "auto" default), the RDCTL_TRANSPORT
+// environment variable, then flagValue as-is.
+func resolveTransport(flagValue string) (machine.Transport, error) {
+	value := flagValue
+	if value == string(machine.TransportAuto) {
+		if envTransport := os.Getenv("RDCTL_TRANSPORT"); envTransport != "" {
+			value = envTransport
 		}
-	} else {
-		err := addLimaBinToPath()
-		if err != nil {
-			return err
-		}
-		err = setupLimaHome()
-		if err != nil {
-			return err
-		}
-		commandName = "limactl"
-		args = append([]string{"shell", "0"}, args...)
 	}
-	fmt.Fprintf(os.Stderr, "QQQ: LIMA_HOME: %s\n", os.Getenv("LIMA_HOME"))
-	blip, err := exec.LookPath("limactl")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "QQQ: Can't find limactl: %s\n", err)
-	} else {
-		fmt.Fprintf(os.Stderr, "QQQ: limactl is at %s\n", blip)
+	return machine.ParseTransport(value)
+}
+
+// splitPositionalVMName recognizes the `rdctl shell <vm> -- <cmd>` form: if
+// args contains a "--", everything before it is the positional VM
+// selector (at most one token) and everything after it is the command to
+// run. If there's no "--", args is returned unchanged and there is no
+// positional selector.
+func splitPositionalVMName(cmd *cobra.Command, args []string) (positionalName string, remainingArgs []string, err error) {
+	dashAt := cmd.ArgsLenAtDash()
+	if dashAt < 0 {
+		return "", args, nil
 	}
-	fmt.Fprintf(os.Stderr, "QQQ: about to launch %s %s\n", commandName, strings.Join(args, " "))
-	shellCommand := exec.Command(commandName, args...)
-	shellCommand.Stdin = os.Stdin
-	shellCommand.Stdout = os.Stdout
-	shellCommand.Stderr = os.Stderr
-	return shellCommand.Run()
+	if dashAt > 1 {
+		return "", nil, fmt.Errorf("rdctl shell: expected at most one VM name before \"--\", got %d", dashAt)
+	}
+	if dashAt == 1 {
+		positionalName = args[0]
+	}
+	return positionalName, args[dashAt:], nil
 }
 
-func addLimaBinToPath() error {
-	_, err := exec.LookPath("limactl")
-	if err == nil {
-		// It's already in the pth
-		return err
+// resolveVMName returns the VM instance to target and whether it was
+// explicitly selected (via --name, a positional selector before "--", or
+// RDCTL_VM) rather than defaulted, in which case it should be validated
+// against the list of known instances. --name/-n takes priority over the
+// positional selector, which in turn takes priority over RDCTL_VM.
+func resolveVMName(defaultName string, positionalName string) (name string, explicit bool) {
+	if vmName != "" {
+		return vmName, true
 	}
-	execPath, err := os.Executable()
-	if err != nil {
-		return err
+	if positionalName != "" {
+		return positionalName, true
 	}
-	fmt.Fprintf(os.Stderr, "QQQ: os.Executable: %s\n", execPath)
-	execPath, err = filepath.EvalSymlinks(execPath)
+	if envName := os.Getenv("RDCTL_VM"); envName != "" {
+		return envName, true
+	}
+	return defaultName, false
+}
+
+// validateVMName checks that name is a VM instance the provider knows
+// about, returning an error listing the available instances if not.
+func validateVMName(provider machine.Provider, name string) error {
+	instances, err := provider.List()
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stderr, "QQQ: os.Executable resolved symlink: %s\n", execPath)
-	candidatePath := path.Join(path.Dir(path.Dir(execPath)), "lima", "bin")
-	notFoundError := fmt.Errorf("no executable limactl file found in %s; try rerunning with the directory containing `limactl` added to PATH", candidatePath)
-	fmt.Fprintf(os.Stderr, "QQQ: Looking for limabin: candidatePath:%s\n", candidatePath)
-	stat, err := os.Stat(path.Join(candidatePath, "limactl"))
-	if err != nil {
-		return notFoundError
+	for _, inst := range instances {
+		if inst.Name == name {
+			return nil
+		}
 	}
-	if uint32(stat.Mode().Perm())&0111 == 0 {
-		return notFoundError
+	available := make([]string, len(instances))
+	for i, inst := range instances {
+		available[i] = inst.Name
 	}
-	os.Setenv("PATH", fmt.Sprintf("%s:%s", candidatePath, os.Getenv("PATH")))
-	return nil
+	return fmt.Errorf("no %s instance named %q; available instances: %s", provider.Name(), name, strings.Join(available, ", "))
 }
 
-func setupLimaHome() error {
-	if os.Getenv("LIMA_HOME") != "" {
-		// It's already in the environment
-		return nil
+func doShellCommand(cmd *cobra.Command, args []string) error {
+	positionalName, args, err := splitPositionalVMName(cmd, args)
+	if err != nil {
+		return err
+	}
+	logging.Debug("shell command invoked", "args", args, "name", vmName, "positionalName", positionalName, "transport", vmTransport)
+	transport, err := resolveTransport(vmTransport)
+	if err != nil {
+		return err
 	}
-	var candidatePath string
-	if runtime.GOOS == "linux" {
-		candidatePath = path.Join(os.Getenv("HOME"), ".local", "share", "rancher-desktop", "lima")
+
+	var name string
+	if runtime.GOOS == "windows" {
+		var explicit bool
+		name, explicit = resolveVMName("", positionalName)
+		if explicit {
+			provider, err := machine.NewProvider()
+			if err != nil {
+				return err
+			}
+			if err := validateVMName(provider, name); err != nil {
+				return err
+			}
+		}
 	} else {
-		candidatePath = path.Join(os.Getenv("HOME"), "Library", "Application Support", "rancher-desktop", "lima")
+		resolvedPaths, err := paths.Get()
+		if err != nil {
+			return err
+		}
+		os.Setenv("PATH", fmt.Sprintf("%s:%s", filepath.Dir(resolvedPaths.LimaCtl()), os.Getenv("PATH")))
+		os.Setenv("LIMA_HOME", resolvedPaths.LimaHome())
+		var explicit bool
+		name, explicit = resolveVMName("0", positionalName)
+		if explicit {
+			provider, err := machine.NewProvider()
+			if err != nil {
+				return err
+			}
+			if err := validateVMName(provider, name); err != nil {
+				return err
+			}
+		}
 	}
-	stat, err := os.Stat(candidatePath)
-	const suggestionMessage = "try rerunning with the environment variable LIMA_HOME set to such a directory"
+
+	backend, err := machine.NewExecBackend(transport, name)
 	if err != nil {
-		return fmt.Errorf("can't find the lima-home directory in the expected spot; %s", suggestionMessage)
-	}
-	if !stat.Mode().IsDir() {
-		return fmt.Errorf("path %s exists but isn't a directory; %s", candidatePath, suggestionMessage)
+		return err
 	}
-	os.Setenv("LIMA_HOME", candidatePath)
-	return nil
+	logging.Debug("launching shell command", "transport", backend.Name(), "instance", name, "args", strings.Join(args, " "))
+	return backend.Exec(name, machine.RunOptions{
+		Args:   args,
+		Dir:    initialWindowsShellDirectory,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
 }
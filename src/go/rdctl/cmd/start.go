@@ -26,6 +26,8 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/logging"
 )
 
 // startCmd represents the start command
@@ -117,7 +119,7 @@ func launchApp(applicationPath string, commandLineArgs []string) error {
 	}
 	// Include this output because there's a delay before the UI comes up.
 	// Without this line, it might look like the command doesn't work.
-	fmt.Fprintf(os.Stderr, "About to launch %s %s ...\n", commandName, strings.Join(args, " "))
+	logging.Info("about to launch", "command", commandName, "args", strings.Join(args, " "))
 	cmd := exec.Command(commandName, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -175,4 +177,4 @@ func checkExistence(candidatePath string, modeBits uint32) string {
 		return ""
 	}
 	return candidatePath
-}
\ No newline at end of file
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging provides the leveled logger used by rdctl's subcommands.
+//
+// By default rdctl only prints output that is meant for the end user; all
+// diagnostic output goes through this package so that it can be silenced or
+// expanded with the `--verbose`/`--debug` flags (or the RDCTL_LOG_LEVEL
+// environment variable) without scattering `if verbose` checks throughout
+// the command implementations.
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// defaultLogger is the logger used by the package-level helper functions.
+// It is replaced by Init once the command-line flags have been parsed.
+var defaultLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+// Init configures the package-level logger according to the --verbose and
+// --debug flags. debug takes precedence over verbose if both are set. If
+// neither is set, the RDCTL_LOG_LEVEL environment variable is consulted
+// (one of "debug", "info", "warn", or "error"); if that is unset or
+// unrecognized, the logger defaults to warn level.
+func Init(verbose bool, debug bool) {
+	level := levelFromEnv()
+	if verbose {
+		level = slog.LevelInfo
+	}
+	if debug {
+		level = slog.LevelDebug
+	}
+	defaultLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+func levelFromEnv() slog.Level {
+	switch strings.ToLower(os.Getenv("RDCTL_LOG_LEVEL")) {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// Debug logs a message only shown with --debug or RDCTL_LOG_LEVEL=debug.
+func Debug(msg string, args ...any) {
+	defaultLogger.Debug(msg, args...)
+}
+
+// Info logs a message shown with --verbose/--debug or a sufficiently
+// permissive RDCTL_LOG_LEVEL.
+func Info(msg string, args ...any) {
+	defaultLogger.Info(msg, args...)
+}
+
+// Warn logs a message that is shown by default.
+func Warn(msg string, args ...any) {
+	defaultLogger.Warn(msg, args...)
+}
+
+// Error logs a message that is shown by default.
+func Error(msg string, args ...any) {
+	defaultLogger.Error(msg, args...)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package machine abstracts the VM backend used by Rancher Desktop (Lima on
+// macOS/Linux, WSL on Windows) behind a single MachineProvider interface, so
+// that rdctl's `machine` subcommands don't need to know which backend is in
+// use.
+package machine
+
+import "io"
+
+// Instance describes a single VM instance managed by the provider.
+type Instance struct {
+	Name   string
+	Status string
+	Arch   string
+	CPUs   int
+	Memory int64
+	Disk   int64
+}
+
+// RunOptions configures an interactive or one-off command run inside an
+// instance via Provider.Run.
+type RunOptions struct {
+	// Args are the command and arguments to run. If empty, an interactive
+	// shell is started.
+	Args []string
+
+	// Dir, if set, is the directory (inside the instance) to start the
+	// command in.
+	Dir string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Provider is implemented once per VM backend (Lima, WSL). It is the
+// abstraction `rdctl machine` is built on top of.
+type Provider interface {
+	// Name identifies the provider, e.g. "lima" or "wsl".
+	Name() string
+
+	// List returns all instances known to the provider.
+	List() ([]Instance, error)
+
+	// Inspect returns details about a single named instance.
+	Inspect(name string) (Instance, error)
+
+	// Init creates a new instance with the given name.
+	Init(name string) error
+
+	// Start starts an existing, stopped instance.
+	Start(name string) error
+
+	// Stop stops a running instance.
+	Stop(name string) error
+
+	// Remove deletes an instance. If force is false, Remove should refuse
+	// to delete a running instance.
+	Remove(name string, force bool) error
+
+	// Run executes a command (or, if opts.Args is empty, an interactive
+	// shell) inside the named instance.
+	Run(name string, opts RunOptions) error
+
+	// Set updates configuration (e.g. CPUs/memory) of an existing instance.
+	Set(name string, cpus int, memoryGiB int) error
+}
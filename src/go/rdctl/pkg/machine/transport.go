@@ -0,0 +1,61 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import "fmt"
+
+// ExecBackend runs a command (or interactive shell) inside a named VM
+// instance. It is a narrower, transport-focused counterpart to Provider:
+// where Provider manages instance lifecycle, ExecBackend only knows how to
+// get a command into an already-running instance, and different
+// implementations trade off startup latency against capability.
+type ExecBackend interface {
+	// Name identifies the backend, e.g. "limactl", "ssh", or "wsl".
+	Name() string
+
+	// Exec runs a command (or, if opts.Args is empty, an interactive
+	// shell) inside the named instance.
+	Exec(instance string, opts RunOptions) error
+}
+
+// Transport selects which ExecBackend to build.
+type Transport string
+
+const (
+	// TransportAuto picks the fastest backend available, falling back to
+	// the VM-native client if it can't.
+	TransportAuto Transport = "auto"
+	// TransportLimactl shells out to `limactl shell` (or `wsl`).
+	TransportLimactl Transport = "limactl"
+	// TransportSSH connects directly over SSH to the instance, skipping
+	// the VM-native client's own startup cost.
+	TransportSSH Transport = "ssh"
+	// TransportWSL shells out to `wsl --exec`. Equivalent to
+	// TransportLimactl on Windows; accepted as its own value so
+	// `--transport` has the same vocabulary on every platform.
+	TransportWSL Transport = "wsl"
+)
+
+// ParseTransport validates a --transport flag value.
+func ParseTransport(value string) (Transport, error) {
+	switch Transport(value) {
+	case TransportAuto, TransportLimactl, TransportSSH, TransportWSL:
+		return Transport(value), nil
+	default:
+		return "", fmt.Errorf("unknown transport %q; must be one of auto, limactl, ssh, wsl", value)
+	}
+}
@@ -0,0 +1,158 @@
+//go:build !windows
+
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// envPassthrough lists the environment variables forwarded to the remote
+// shell by sshExecBackend, mirroring what an interactive `ssh` login would
+// normally inherit from the user's own terminal.
+var envPassthrough = []string{"TERM", "LANG", "LC_ALL", "COLORTERM"}
+
+// NewExecBackend returns the ExecBackend for the requested transport and
+// instance. TransportAuto prefers ssh (it skips limactl's own startup
+// cost) and falls back to limactl if the instance's ssh.config can't be
+// found, e.g. because the instance hasn't been started yet.
+func NewExecBackend(transport Transport, instance string) (ExecBackend, error) {
+	switch transport {
+	case TransportSSH:
+		return newSSHExecBackend(instance)
+	case TransportLimactl, TransportWSL:
+		return limactlExecBackend{}, nil
+	case TransportAuto, "":
+		if backend, err := newSSHExecBackend(instance); err == nil {
+			return backend, nil
+		}
+		return limactlExecBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported transport %q on this platform", transport)
+	}
+}
+
+// limactlExecBackend shells out to `limactl shell`, the original (slower
+// but always-available) transport.
+type limactlExecBackend struct{}
+
+func (limactlExecBackend) Name() string { return "limactl" }
+
+func (limactlExecBackend) Exec(instance string, opts RunOptions) error {
+	args := append([]string{"shell", instance}, opts.Args...)
+	cmd := exec.Command("limactl", args...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+// sshExecBackend connects directly to the instance's SSH port, as
+// published in the ssh.config Lima writes into the instance directory.
+// This avoids spawning a full `limactl` client, shaving its ~300ms
+// startup cost off of every `rdctl shell` invocation.
+type sshExecBackend struct {
+	limaHome string
+}
+
+// newSSHExecBackend builds the ssh backend, failing if the instance's
+// ssh.config doesn't exist yet so callers (notably TransportAuto) can
+// fall back to limactl before committing to this backend.
+func newSSHExecBackend(instance string) (ExecBackend, error) {
+	resolvedPaths, err := paths.Get()
+	if err != nil {
+		return nil, err
+	}
+	sshConfigPath := filepath.Join(resolvedPaths.LimaHome(), instance, "ssh.config")
+	if _, err := os.Stat(sshConfigPath); err != nil {
+		return nil, fmt.Errorf("no ssh.config found for instance %q (is it running?): %w", instance, err)
+	}
+	return sshExecBackend{limaHome: resolvedPaths.LimaHome()}, nil
+}
+
+func (sshExecBackend) Name() string { return "ssh" }
+
+func (b sshExecBackend) Exec(instance string, opts RunOptions) error {
+	sshConfigPath := filepath.Join(b.limaHome, instance, "ssh.config")
+	host, err := sshConfigHost(sshConfigPath)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-F", sshConfigPath}
+	if len(opts.Args) == 0 {
+		// Request a TTY for an interactive shell.
+		args = append(args, "-t")
+	}
+	args = append(args, host)
+	if remoteCommand := withEnvPassthrough(opts.Args); len(remoteCommand) > 0 {
+		args = append(args, remoteCommand...)
+	}
+
+	cmd := exec.Command("ssh", args...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+// sshConfigHost returns the `Host` alias declared at the top of a
+// Lima-generated ssh.config (conventionally "lima-<instance>").
+func sshConfigHost(sshConfigPath string) (string, error) {
+	file, err := os.Open(sshConfigPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.EqualFold(fields[0], "Host") {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("no Host entry found in %s", sshConfigPath)
+}
+
+// withEnvPassthrough wraps args (if any) in a remote `env` invocation that
+// forwards envPassthrough, the way the local shell would normally see
+// them, since plain ssh doesn't forward the client's environment unless
+// the server has been configured to AcceptEnv it.
+func withEnvPassthrough(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	var assignments []string
+	for _, key := range envPassthrough {
+		if value, ok := os.LookupEnv(key); ok {
+			assignments = append(assignments, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	if len(assignments) == 0 {
+		return args
+	}
+	return append(append([]string{"env"}, assignments...), args...)
+}
@@ -0,0 +1,63 @@
+//go:build windows
+
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// NewExecBackend returns the ExecBackend for the requested transport and
+// instance. WSL doesn't expose a stable per-distribution SSH endpoint the
+// way Lima does, so "ssh" isn't available on Windows and returns an error;
+// "auto", "limactl" and "wsl" all use the wsl backend.
+func NewExecBackend(transport Transport, instance string) (ExecBackend, error) {
+	switch transport {
+	case TransportAuto, TransportWSL, TransportLimactl, "":
+		return wslExecBackend{}, nil
+	case TransportSSH:
+		return nil, fmt.Errorf("the ssh transport is not supported on Windows; use --transport=wsl")
+	default:
+		return nil, fmt.Errorf("unsupported transport %q on this platform", transport)
+	}
+}
+
+// wslExecBackend shells out to `wsl --exec`.
+type wslExecBackend struct{}
+
+func (wslExecBackend) Name() string { return "wsl" }
+
+func (wslExecBackend) Exec(instance string, opts RunOptions) error {
+	var args []string
+	if instance != "" {
+		args = append(args, "-d", instance)
+	}
+	if opts.Dir != "" {
+		args = append(args, "--cd", opts.Dir)
+	}
+	if len(opts.Args) > 0 {
+		args = append(args, "--exec")
+		args = append(args, opts.Args...)
+	}
+	cmd := exec.Command("wsl.exe", args...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
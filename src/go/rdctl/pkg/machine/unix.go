@@ -0,0 +1,146 @@
+//go:build !windows
+
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// limaProvider implements Provider on top of `limactl`, used on macOS and
+// Linux.
+type limaProvider struct{}
+
+// NewProvider returns the Provider for the current platform.
+func NewProvider() (Provider, error) {
+	resolvedPaths, err := paths.Get()
+	if err != nil {
+		return nil, err
+	}
+	os.Setenv("PATH", fmt.Sprintf("%s:%s", filepath.Dir(resolvedPaths.LimaCtl()), os.Getenv("PATH")))
+	os.Setenv("LIMA_HOME", resolvedPaths.LimaHome())
+	return limaProvider{}, nil
+}
+
+func (limaProvider) Name() string {
+	return "lima"
+}
+
+type limaInstance struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Arch   string `json:"arch"`
+	CPUs   int    `json:"cpus"`
+	Memory int64  `json:"memory"`
+	Disk   int64  `json:"disk"`
+}
+
+func (limaProvider) List() ([]Instance, error) {
+	out, err := exec.Command("limactl", "list", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lima instances: %w", err)
+	}
+	var instances []Instance
+	// `limactl list --json` emits one JSON object per instance, separated
+	// by newlines, rather than a single JSON array.
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var inst limaInstance
+		if err := decoder.Decode(&inst); err != nil {
+			break
+		}
+		instances = append(instances, Instance(inst))
+	}
+	return instances, nil
+}
+
+func (p limaProvider) Inspect(name string) (Instance, error) {
+	instances, err := p.List()
+	if err != nil {
+		return Instance{}, err
+	}
+	for _, inst := range instances {
+		if inst.Name == name {
+			return inst, nil
+		}
+	}
+	return Instance{}, fmt.Errorf("no lima instance named %q", name)
+}
+
+func (limaProvider) Init(name string) error {
+	cmd := exec.Command("limactl", "start", "--name", name, "--tty=false")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (limaProvider) Start(name string) error {
+	cmd := exec.Command("limactl", "start", name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (limaProvider) Stop(name string) error {
+	cmd := exec.Command("limactl", "stop", name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (limaProvider) Remove(name string, force bool) error {
+	args := []string{"delete"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, name)
+	cmd := exec.Command("limactl", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (limaProvider) Run(name string, opts RunOptions) error {
+	args := append([]string{"shell", name}, opts.Args...)
+	cmd := exec.Command("limactl", args...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+func (limaProvider) Set(name string, cpus int, memoryGiB int) error {
+	args := []string{"edit", name}
+	if cpus > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%d", cpus))
+	}
+	if memoryGiB > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%d", memoryGiB))
+	}
+	cmd := exec.Command("limactl", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,176 @@
+//go:build windows
+
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf16"
+)
+
+// wslProvider implements Provider on top of `wsl.exe`, wrapping WSL
+// distributions the same way limaProvider wraps Lima instances.
+type wslProvider struct{}
+
+// NewProvider returns the Provider for the current platform.
+func NewProvider() (Provider, error) {
+	return wslProvider{}, nil
+}
+
+func (wslProvider) Name() string {
+	return "wsl"
+}
+
+// wslCommand runs `wsl.exe` and decodes its UTF-16LE output, which is how
+// wsl.exe writes to redirected (non-console) stdout.
+func wslCommand(args ...string) ([]string, error) {
+	out, err := exec.Command("wsl.exe", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeUTF16(out)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(decoded))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func decodeUTF16(b []byte) (string, error) {
+	// wsl.exe prefixes redirected stdout with a UTF-16LE byte order mark;
+	// strip it so callers don't see a stray U+FEFF on the first line.
+	b = bytes.TrimPrefix(b, []byte{0xFF, 0xFE})
+	if len(b)%2 != 0 {
+		return "", fmt.Errorf("invalid UTF-16 output: odd length")
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+func (wslProvider) List() ([]Instance, error) {
+	lines, err := wslCommand("--list", "--verbose")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WSL distributions: %w", err)
+	}
+	var instances []Instance
+	for _, line := range lines {
+		// Header line: "  NAME STATE VERSION"
+		if strings.HasPrefix(strings.TrimSpace(line), "NAME") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		if len(fields) < 2 {
+			continue
+		}
+		instances = append(instances, Instance{Name: fields[0], Status: fields[1]})
+	}
+	return instances, nil
+}
+
+// isDistributionRunning reports whether name is an exact match for one of
+// the distributions `wsl --list --running` reports, rather than a
+// substring match against the raw output (which would also match
+// unrelated distros sharing a name prefix, e.g. "Ubuntu" vs.
+// "Ubuntu-22.04").
+func isDistributionRunning(name string) (bool, error) {
+	lines, err := wslCommand("--list", "--running")
+	if err != nil {
+		return false, fmt.Errorf("failed to list running WSL distributions: %w", err)
+	}
+	for _, line := range lines {
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		if len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p wslProvider) Inspect(name string) (Instance, error) {
+	instances, err := p.List()
+	if err != nil {
+		return Instance{}, err
+	}
+	for _, inst := range instances {
+		if inst.Name == name {
+			return inst, nil
+		}
+	}
+	return Instance{}, fmt.Errorf("no WSL distribution named %q", name)
+}
+
+func (wslProvider) Init(name string) error {
+	return fmt.Errorf("creating new WSL distributions is not supported; import one with `wsl --import %s <path> <tarball>`", name)
+}
+
+func (wslProvider) Start(name string) error {
+	cmd := exec.Command("wsl.exe", "-d", name, "--", "true")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (wslProvider) Stop(name string) error {
+	cmd := exec.Command("wsl.exe", "--terminate", name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (wslProvider) Remove(name string, force bool) error {
+	if !force {
+		if running, err := isDistributionRunning(name); err == nil && running {
+			return fmt.Errorf("%s is running; stop it first or pass --force", name)
+		}
+	}
+	cmd := exec.Command("wsl.exe", "--unregister", name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (wslProvider) Run(name string, opts RunOptions) error {
+	args := []string{"-d", name}
+	if len(opts.Args) > 0 {
+		args = append(args, "--exec")
+		args = append(args, opts.Args...)
+	}
+	cmd := exec.Command("wsl.exe", args...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+func (wslProvider) Set(name string, cpus int, memoryGiB int) error {
+	return fmt.Errorf("per-distribution resource limits are configured globally via %%UserProfile%%\\.wslconfig, not per-instance")
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16BOM builds a UTF-16LE byte stream with the leading byte order
+// mark that wsl.exe prepends to redirected stdout.
+func encodeUTF16BOM(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := []byte{0xFF, 0xFE}
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestDecodeUTF16StripsBOM(t *testing.T) {
+	got, err := decodeUTF16(encodeUTF16BOM("NAME\r\n"))
+	if err != nil {
+		t.Fatalf("decodeUTF16 returned an error: %v", err)
+	}
+	if strings.ContainsRune(got, '\ufeff') {
+		t.Fatalf("decodeUTF16 left a BOM in the output: %q", got)
+	}
+	if !strings.HasPrefix(got, "NAME") {
+		t.Fatalf("decodeUTF16 returned %q, expected it to start with NAME", got)
+	}
+}
+
+func TestDecodeUTF16KeepsHeaderRecognizable(t *testing.T) {
+	decoded, err := decodeUTF16(encodeUTF16BOM("  NAME      STATE           VERSION\r\n* Ubuntu    Running         2\r\n"))
+	if err != nil {
+		t.Fatalf("decodeUTF16 returned an error: %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(decoded, "\n") {
+		if line := strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 || strings.HasPrefix(lines[0], "\ufeff") {
+		t.Fatalf("header line still carries a BOM: %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("first line %q should be the header, not a parsed instance", lines[0])
+	}
+}
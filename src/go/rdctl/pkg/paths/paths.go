@@ -0,0 +1,171 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package paths resolves the on-disk locations rdctl needs (the bundled
+// `limactl` binary, Lima's home directory, the rdctl executable itself),
+// honoring XDG_DATA_HOME on Linux the way the rest of Rancher Desktop
+// does, and caching the result so repeated calls don't re-run
+// exec.LookPath/os.Stat/symlink resolution.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// Paths holds the resolved locations used by rdctl. Use Get to obtain the
+// process-wide, cached instance.
+type Paths struct {
+	limaCtl       string
+	limaHome      string
+	appExecutable string
+}
+
+var (
+	cached    Paths
+	cachedErr error
+	cacheOnce sync.Once
+)
+
+// Get returns the cached, resolved Paths, computing it on first call.
+func Get() (Paths, error) {
+	cacheOnce.Do(func() {
+		cached, cachedErr = resolve()
+	})
+	return cached, cachedErr
+}
+
+// reset clears the cache, so tests can exercise resolve() more than once
+// within the same process (see TestGetCachesResult).
+func reset() {
+	cacheOnce = sync.Once{}
+	cached = Paths{}
+	cachedErr = nil
+}
+
+// LimaCtl returns the path to the `limactl` executable.
+func (p Paths) LimaCtl() string {
+	return p.limaCtl
+}
+
+// LimaHome returns the directory to use as LIMA_HOME.
+func (p Paths) LimaHome() string {
+	return p.limaHome
+}
+
+// AppExecutable returns the resolved, symlink-free path to the running
+// rdctl executable.
+func (p Paths) AppExecutable() string {
+	return p.appExecutable
+}
+
+func resolve() (Paths, error) {
+	appExecutable, err := resolveAppExecutable()
+	if err != nil {
+		return Paths{}, err
+	}
+	limaCtl, err := resolveLimaCtl(appExecutable)
+	if err != nil {
+		return Paths{}, err
+	}
+	limaHome, err := resolveLimaHome()
+	if err != nil {
+		return Paths{}, err
+	}
+	return Paths{limaCtl: limaCtl, limaHome: limaHome, appExecutable: appExecutable}, nil
+}
+
+func resolveAppExecutable() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(execPath)
+}
+
+// resolveLimaCtl finds the `limactl` binary, preferring one already on
+// PATH and otherwise looking next to the rdctl executable (in
+// "../lima/bin/limactl", matching the app bundle layout).
+func resolveLimaCtl(appExecutable string) (string, error) {
+	if limaCtl, err := exec.LookPath("limactl"); err == nil {
+		return limaCtl, nil
+	}
+	candidatePath := filepath.Join(filepath.Dir(filepath.Dir(appExecutable)), "lima", "bin", "limactl")
+	notFoundError := fmt.Errorf("no executable limactl file found in %s; try rerunning with the directory containing `limactl` added to PATH", filepath.Dir(candidatePath))
+	stat, err := os.Stat(candidatePath)
+	if err != nil {
+		return "", notFoundError
+	}
+	if uint32(stat.Mode().Perm())&0111 == 0 {
+		return "", notFoundError
+	}
+	return candidatePath, nil
+}
+
+// resolveLimaHome determines the directory to use as LIMA_HOME. LIMA_HOME
+// in the environment always wins; otherwise it defaults to the XDG data
+// directory on Linux (honoring XDG_DATA_HOME) or the Application Support
+// directory on macOS.
+func resolveLimaHome() (string, error) {
+	if limaHome := os.Getenv("LIMA_HOME"); limaHome != "" {
+		return limaHome, nil
+	}
+	candidatePath, err := defaultLimaHome()
+	if err != nil {
+		return "", err
+	}
+	const suggestionMessage = "try rerunning with the environment variable LIMA_HOME set to such a directory"
+	stat, err := os.Stat(candidatePath)
+	if err != nil {
+		return "", fmt.Errorf("can't find the lima-home directory in the expected spot; %s", suggestionMessage)
+	}
+	if !stat.Mode().IsDir() {
+		return "", fmt.Errorf("path %s exists but isn't a directory; %s", candidatePath, suggestionMessage)
+	}
+	return candidatePath, nil
+}
+
+func defaultLimaHome() (string, error) {
+	dataHome, err := dataHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, "rancher-desktop", "lima"), nil
+}
+
+// dataHomeDir returns the base directory rdctl stores its data under,
+// honoring $XDG_DATA_HOME on Linux and falling back to $HOME-relative
+// defaults everywhere else.
+func dataHomeDir() (string, error) {
+	home := os.Getenv("HOME")
+	if runtime.GOOS == "linux" {
+		if xdgDataHome := os.Getenv("XDG_DATA_HOME"); xdgDataHome != "" {
+			return xdgDataHome, nil
+		}
+		if home == "" {
+			return "", fmt.Errorf("neither XDG_DATA_HOME nor HOME is set")
+		}
+		return filepath.Join(home, ".local", "share"), nil
+	}
+	if home == "" {
+		return "", fmt.Errorf("HOME is not set")
+	}
+	return filepath.Join(home, "Library", "Application Support"), nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2022 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestResolveLimaCtlMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	appDir := t.TempDir()
+	appExecutable := filepath.Join(appDir, "app", "rdctl")
+	if _, err := resolveLimaCtl(appExecutable); err == nil {
+		t.Fatal("expected an error when limactl is not present, got nil")
+	}
+}
+
+func TestResolveLimaCtlNotExecutable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	appDir := t.TempDir()
+	limaBinDir := filepath.Join(appDir, "lima", "bin")
+	if err := os.MkdirAll(limaBinDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	limaCtlPath := filepath.Join(limaBinDir, "limactl")
+	if err := os.WriteFile(limaCtlPath, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	appExecutable := filepath.Join(appDir, "bin", "rdctl")
+	if _, err := resolveLimaCtl(appExecutable); err == nil {
+		t.Fatal("expected an error when limactl is not executable, got nil")
+	}
+}
+
+func TestResolveLimaCtlSymlinkedInstall(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	installDir := t.TempDir()
+	limaBinDir := filepath.Join(installDir, "lima", "bin")
+	if err := os.MkdirAll(limaBinDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	limaCtlPath := filepath.Join(limaBinDir, "limactl")
+	if err := os.WriteFile(limaCtlPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an install where the executable rdctl invokes is a symlink
+	// into the real install directory (e.g. a Homebrew Cellar layout).
+	installBinDir := filepath.Join(installDir, "bin")
+	if err := os.MkdirAll(installBinDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(installBinDir, "rdctl"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	linkDir := t.TempDir()
+	appExecutable := filepath.Join(linkDir, "rdctl")
+	if err := os.Symlink(filepath.Join(installBinDir, "rdctl"), appExecutable); err != nil {
+		t.Fatal(err)
+	}
+	// resolveAppExecutable resolves the executable path itself, not its
+	// directory, so the test must do the same.
+	resolved, err := filepath.EvalSymlinks(appExecutable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := resolveLimaCtl(resolved)
+	if err != nil {
+		t.Fatalf("resolveLimaCtl failed for a symlinked install layout: %v", err)
+	}
+	if got != limaCtlPath {
+		t.Fatalf("resolveLimaCtl returned %q, expected %q", got, limaCtlPath)
+	}
+}
+
+func TestDataHomeDirMissingHome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_DATA_HOME fallback behavior is Linux-specific")
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "")
+	if _, err := dataHomeDir(); err == nil {
+		t.Fatal("expected an error when neither XDG_DATA_HOME nor HOME is set, got nil")
+	}
+}
+
+func TestDataHomeDirHonorsXDG(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_DATA_HOME fallback behavior is Linux-specific")
+	}
+	t.Setenv("XDG_DATA_HOME", "/custom/data/home")
+	t.Setenv("HOME", "/home/someone")
+	got, err := dataHomeDir()
+	if err != nil {
+		t.Fatalf("dataHomeDir returned an error: %v", err)
+	}
+	if got != "/custom/data/home" {
+		t.Fatalf("dataHomeDir returned %q, expected XDG_DATA_HOME to take precedence", got)
+	}
+}
+
+func TestGetCachesResult(t *testing.T) {
+	reset()
+	t.Cleanup(reset)
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "limactl"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	limaHomeA := t.TempDir()
+	t.Setenv("LIMA_HOME", limaHomeA)
+	first, err := Get()
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if first.LimaHome() != limaHomeA {
+		t.Fatalf("Get returned LimaHome %q, expected %q", first.LimaHome(), limaHomeA)
+	}
+
+	limaHomeB := t.TempDir()
+	t.Setenv("LIMA_HOME", limaHomeB)
+	second, err := Get()
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if second.LimaHome() != limaHomeA {
+		t.Fatalf("Get returned LimaHome %q on a second call, expected the cached %q (LIMA_HOME changed after the first call, but Get should not re-resolve)", second.LimaHome(), limaHomeA)
+	}
+
+	reset()
+	third, err := Get()
+	if err != nil {
+		t.Fatalf("Get returned an error after reset: %v", err)
+	}
+	if third.LimaHome() != limaHomeB {
+		t.Fatalf("Get returned LimaHome %q after reset, expected the fresh %q", third.LimaHome(), limaHomeB)
+	}
+}
+
+func TestDataHomeDirFallsBackToHome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_DATA_HOME fallback behavior is Linux-specific")
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "/home/someone")
+	got, err := dataHomeDir()
+	if err != nil {
+		t.Fatalf("dataHomeDir returned an error: %v", err)
+	}
+	if got != "/home/someone/.local/share" {
+		t.Fatalf("dataHomeDir returned %q, expected the XDG default under HOME", got)
+	}
+}